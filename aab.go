@@ -0,0 +1,285 @@
+package paser
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	aabExt = ".aab"
+
+	bundleConfigPath = "BundleConfig.pb"
+	baseManifestPath = "base/manifest/AndroidManifest.xml"
+	manifestPbSuffix = "/manifest/AndroidManifest.xml"
+	baseModuleName   = "base"
+)
+
+// Format 标识解析的安装包格式
+type Format int
+
+const (
+	FormatAPK Format = iota
+	FormatAAB
+)
+
+// ErrIconUnsupportedForAAB在NewAppParser以isIcon=true解析.aab时返回：图标/
+// 本地化名称解析依赖AXML resources.arsc链路，而AAB的manifest是protobuf编码，
+// 这条链路尚未实现，宁可显式报错也不要静默返回一个没有图标/名称的AppInfo。
+var ErrIconUnsupportedForAAB = errors.New("paser: icon/label parsing is not yet supported for Android App Bundle (.aab)")
+
+// ModuleInfo 描述AAB中的一个功能/资源模块
+type ModuleInfo struct {
+	Name      string // 模块名，如 base、dynamic_feature、config.arm64_v8a
+	IsFeature bool   // 是否为按需下载的feature模块
+	Assets    []string
+	HasDex    bool
+	HasLib    bool
+}
+
+// parseBundleFile 解析AAB(Android App Bundle)：其AndroidManifest.xml为protobuf
+// 编码(非AXML)，因此manifest/权限/模块信息均需要通过protobuf wire格式解析获得，
+// 而不能复用parseAndroidManifest中的AXML解码链路。
+func parseBundleFile(reader *zip.Reader) (*AppInfo, error) {
+	modules := map[string]*ModuleInfo{}
+	var baseManifest *zip.File
+
+	for _, f := range reader.File {
+		if f.Name == baseManifestPath {
+			baseManifest = f
+		}
+		moduleName, rel, ok := splitModulePath(f.Name)
+		if !ok {
+			continue
+		}
+		m, exists := modules[moduleName]
+		if !exists {
+			m = &ModuleInfo{Name: moduleName, IsFeature: moduleName != baseModuleName}
+			modules[moduleName] = m
+		}
+		switch {
+		case strings.HasPrefix(rel, "assets/"):
+			m.Assets = append(m.Assets, rel)
+		case strings.HasPrefix(rel, "dex/"):
+			m.HasDex = true
+		case strings.HasPrefix(rel, "lib/"):
+			m.HasLib = true
+		}
+	}
+
+	if baseManifest == nil {
+		return nil, errors.New("base/manifest/AndroidManifest.xml not found")
+	}
+
+	rc, err := baseManifest.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := parseProtoManifest(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Format = FormatAAB
+	for _, m := range modules {
+		info.Modules = append(info.Modules, *m)
+	}
+	return info, nil
+}
+
+// splitModulePath 将形如 "dynamic_feature/assets/foo" 的bundle内部路径拆分为
+// 模块名与模块内相对路径。非模块文件（如BundleConfig.pb、META-INF/与
+// BUNDLE-METADATA/下的文件）返回ok=false。
+func splitModulePath(name string) (module, rel string, ok bool) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	if parts[0] == "META-INF" || parts[0] == "BUNDLE-METADATA" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// parseProtoManifest 解析protobuf编码的AndroidManifest.xml(aapt2 XmlNode消息)，
+// 提取package/versionCode/versionName/uses-permission，结构与parseApkFile保持一致。
+func parseProtoManifest(buf []byte) (*AppInfo, error) {
+	root, err := decodeProtoFields(buf)
+	if err != nil {
+		return nil, err
+	}
+	element := firstMessageField(root, xmlNodeElementField)
+	if element == nil {
+		return nil, errors.New("AndroidManifest.xml: root <manifest> element not found")
+	}
+	manifestEl, err := decodeProtoFields(element)
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(AppInfo)
+	for _, attr := range xmlAttributes(manifestEl) {
+		switch attr.name {
+		case "package":
+			info.BundleId = attr.value
+		case "versionName":
+			info.Version = attr.value
+		case "versionCode":
+			if v, errAtoi := strconv.Atoi(attr.value); errAtoi == nil {
+				info.Build = v
+			}
+		}
+	}
+
+	for _, childBytes := range messageFields(manifestEl, xmlElementChildField) {
+		childFields, errChild := decodeProtoFields(childBytes)
+		if errChild != nil {
+			continue
+		}
+		childElement := firstMessageField(childFields, xmlNodeElementField)
+		if childElement == nil {
+			continue
+		}
+		childEl, errEl := decodeProtoFields(childElement)
+		if errEl != nil {
+			continue
+		}
+		if xmlElementName(childEl) != "uses-permission" {
+			continue
+		}
+		for _, attr := range xmlAttributes(childEl) {
+			if attr.name == "name" {
+				info.UsesPermission = append(info.UsesPermission, attr.value)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// --- 最小化的protobuf wire格式解析，仅支持本文件所需的aapt2 Resources.proto子集 ---
+// XmlNode   { 1 source_position, 2 XmlElement element, 3 string text }
+// XmlElement{ 1 namespace_declaration, 2 namespace_uri, 3 string name, 4 XmlAttribute attribute, 5 XmlNode child }
+// XmlAttribute { 1 namespace_uri, 2 string name, 3 string value, 4 resource_id, 5 compiled_item }
+const (
+	xmlNodeElementField    = 2
+	xmlElementNameField    = 3
+	xmlElementAttrField    = 4
+	xmlElementChildField   = 5
+	xmlAttributeNameField  = 2
+	xmlAttributeValueField = 3
+)
+
+type protoField struct {
+	num      int
+	wireType int
+	value    []byte
+}
+
+// decodeProtoFields 对protobuf消息做一次不依赖schema的wire格式扫描，
+// 返回按出现顺序排列的字段列表；length-delimited字段的value为原始子消息/字符串字节。
+func decodeProtoFields(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for i := 0; i < len(b); {
+		key, n := binary.Uvarint(b[i:])
+		if n <= 0 {
+			return nil, errors.New("invalid protobuf varint tag")
+		}
+		i += n
+		fieldNum := int(key >> 3)
+		wireType := int(key & 0x7)
+
+		switch wireType {
+		case 0: // varint
+			_, n := binary.Uvarint(b[i:])
+			if n <= 0 {
+				return nil, errors.New("invalid protobuf varint value")
+			}
+			fields = append(fields, protoField{num: fieldNum, wireType: wireType, value: b[i : i+n]})
+			i += n
+		case 1: // 64-bit
+			if i+8 > len(b) {
+				return nil, errors.New("truncated protobuf fixed64")
+			}
+			fields = append(fields, protoField{num: fieldNum, wireType: wireType, value: b[i : i+8]})
+			i += 8
+		case 2: // length-delimited
+			l, n := binary.Uvarint(b[i:])
+			// l来自文件内容，先与剩余字节数比较，避免l接近math.MaxUint64时
+			// int(l)溢出成负数，从而绕过下面本应失败的边界检查。
+			if n <= 0 || l > uint64(len(b)-i-n) {
+				return nil, errors.New("invalid protobuf length-delimited field")
+			}
+			i += n
+			fields = append(fields, protoField{num: fieldNum, wireType: wireType, value: b[i : i+int(l)]})
+			i += int(l)
+		case 5: // 32-bit
+			if i+4 > len(b) {
+				return nil, errors.New("truncated protobuf fixed32")
+			}
+			fields = append(fields, protoField{num: fieldNum, wireType: wireType, value: b[i : i+4]})
+			i += 4
+		default:
+			return nil, errors.New("unsupported protobuf wire type")
+		}
+	}
+	return fields, nil
+}
+
+func firstMessageField(fields []protoField, num int) []byte {
+	for _, f := range fields {
+		if f.num == num && f.wireType == 2 {
+			return f.value
+		}
+	}
+	return nil
+}
+
+func messageFields(fields []protoField, num int) [][]byte {
+	var out [][]byte
+	for _, f := range fields {
+		if f.num == num && f.wireType == 2 {
+			out = append(out, f.value)
+		}
+	}
+	return out
+}
+
+func xmlElementName(el []protoField) string {
+	if v := firstMessageField(el, xmlElementNameField); v != nil {
+		return string(v)
+	}
+	return ""
+}
+
+type xmlAttr struct {
+	name  string
+	value string
+}
+
+func xmlAttributes(el []protoField) []xmlAttr {
+	var attrs []xmlAttr
+	for _, attrBytes := range messageFields(el, xmlElementAttrField) {
+		attrFields, err := decodeProtoFields(attrBytes)
+		if err != nil {
+			continue
+		}
+		attrs = append(attrs, xmlAttr{
+			name:  string(firstMessageField(attrFields, xmlAttributeNameField)),
+			value: string(firstMessageField(attrFields, xmlAttributeValueField)),
+		})
+	}
+	return attrs
+}
@@ -0,0 +1,52 @@
+package paser
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// NewAppParser对.aab以isIcon=true调用时，曾经静默忽略isIcon(图标/本地化
+// 名称解析依赖的AXML resources.arsc链路尚未支持AAB的protobuf manifest)，
+// 该测试确保现在显式返回ErrIconUnsupportedForAAB而不是悄悄跳过。
+func TestNewAppParserReturnsErrIconUnsupportedForAAB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.aab")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create temp aab: %v", err)
+	}
+	if err := zip.NewWriter(f).Close(); err != nil {
+		t.Fatalf("write empty zip: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close temp aab: %v", err)
+	}
+
+	_, err = NewAppParser(path, true)
+	if err != ErrIconUnsupportedForAAB {
+		t.Fatalf("NewAppParser(%q, true) err = %v, want ErrIconUnsupportedForAAB", path, err)
+	}
+}
+
+// 一个length-delimited字段声明了接近math.MaxUint64的长度，曾经因为int(l)溢出
+// 成负数而绕过边界检查，导致b[i:i+int(l)]以负索引panic；该测试确保现在改为
+// 返回错误。
+func TestDecodeProtoFieldsRejectsOversizedLength(t *testing.T) {
+	// tag: field 1, wire type 2 (length-delimited) -> (1<<3)|2 = 0x0a
+	// length: 0xffffffffffffffff 编码为10字节varint
+	b := []byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+
+	if _, err := decodeProtoFields(b); err == nil {
+		t.Fatal("expected error for oversized protobuf length, got nil")
+	}
+}
+
+func TestDecodeProtoFieldsRejectsTruncatedLength(t *testing.T) {
+	// length声明为5字节，但只剩2字节可用
+	b := []byte{0x0a, 0x05, 0x01, 0x02}
+
+	if _, err := decodeProtoFields(b); err == nil {
+		t.Fatal("expected error for truncated protobuf length-delimited field, got nil")
+	}
+}
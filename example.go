@@ -9,7 +9,7 @@ import (
 
 func main() {
 	apkFile := "com.xxx.9.7_32bit.apk"
-	app, err := NewAppParser(apkFile, "keytool", false)
+	app, err := NewAppParser(apkFile, false)
 	marshal, err := json.Marshal(app)
 	if err != nil {
 		return
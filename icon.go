@@ -0,0 +1,105 @@
+package paser
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Android密度限定符对应的dpi桶，参见 https://developer.android.com/training/multiscreen/screendensities
+const (
+	DensityLDPI    = 120
+	DensityMDPI    = 160
+	DensityTVDPI   = 213
+	DensityHDPI    = 240
+	DensityXHDPI   = 320
+	DensityXXHDPI  = 480
+	DensityXXXHDPI = 640
+	DensityAnyDPI  = 0xfffe // anydpi，通常用于矢量/自适应图标
+)
+
+// adaptiveIconCanvasDp/adaptiveIconSafeDp为自适应图标画布与安全区的dp尺寸：
+// 画布108dp，中心72dp为安全区(会被裁剪为圆形)。
+const (
+	adaptiveIconCanvasDp float64 = 108
+	adaptiveIconSafeDp   float64 = 72
+)
+
+// AdaptiveIcon保存自适应图标(API 26+ <adaptive-icon>)的原始前景/背景图层，
+// 未经裁剪，供调用方自行合成其他形状(圆角矩形、水滴形等)。
+type AdaptiveIcon struct {
+	Foreground image.Image
+	Background image.Image
+}
+
+// parseApkIconAndLabel解析apk图标(按dpi分组)、按locale分组的名称，以及可能
+// 存在的自适应图标图层。
+func parseApkIconAndLabel(name string) (map[int]image.Image, map[string]string, *AdaptiveIcon, error) {
+	pkg, err := openFile(name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer func() {
+		_ = pkg.close()
+	}()
+
+	icons, _ := pkg.icons()
+	adaptive, _ := pkg.adaptiveIcon()
+	labels, _ := pkg.labels()
+
+	return icons, labels, adaptive, nil
+}
+
+// pickRepresentativeIcon从按dpi分组的图标集合中选取一张代表图标：优先xxhdpi，
+// 否则取已有密度中最高的一张。
+func pickRepresentativeIcon(icons map[int]image.Image) image.Image {
+	if icon, ok := icons[DensityXXHDPI]; ok {
+		return icon
+	}
+	var (
+		best    image.Image
+		bestDpi = -1
+	)
+	for dpi, icon := range icons {
+		if dpi > bestDpi {
+			bestDpi = dpi
+			best = icon
+		}
+	}
+	return best
+}
+
+// compositeAdaptiveIcon将自适应图标的前景/背景图层合成为单张图标：画布边长取两个
+// 图层中较宽者，视为108dp；两个图层按原始像素尺寸从左上角对齐绘制，不做缩放。
+// 合成后以直径72dp(安全区宽度)的居中圆形做遮罩，圆外像素为透明。
+func compositeAdaptiveIcon(icon *AdaptiveIcon) image.Image {
+	if icon == nil || icon.Background == nil || icon.Foreground == nil {
+		return nil
+	}
+
+	size := icon.Background.Bounds().Dx()
+	if fgSize := icon.Foreground.Bounds().Dx(); fgSize > size {
+		size = fgSize
+	}
+	if size <= 0 {
+		return nil
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(canvas, canvas.Bounds(), icon.Background, icon.Background.Bounds().Min, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), icon.Foreground, icon.Foreground.Bounds().Min, draw.Over)
+
+	radius := float64(size) * (adaptiveIconSafeDp / adaptiveIconCanvasDp) / 2
+	center := float64(size) / 2
+	masked := image.NewRGBA(canvas.Bounds())
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x) + 0.5 - center
+			dy := float64(y) + 0.5 - center
+			if math.Hypot(dx, dy) <= radius {
+				masked.Set(x, y, canvas.At(x, y))
+			}
+		}
+	}
+	return masked
+}
@@ -0,0 +1,35 @@
+package paser
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func solidImage(size int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	return img
+}
+
+// adaptiveIconSafeDp/adaptiveIconCanvasDp曾经是untyped int常量相除，在float64
+// 转换前就被截断为0，导致圆形遮罩半径恒为0、合成结果全透明。该测试确保合成
+// 出的图标中心一定有可见像素。
+func TestCompositeAdaptiveIconProducesVisiblePixels(t *testing.T) {
+	const size = 108
+	icon := &AdaptiveIcon{
+		Background: solidImage(size, color.RGBA{R: 0xff, A: 0xff}),
+		Foreground: solidImage(size, color.RGBA{G: 0xff, A: 0xff}),
+	}
+
+	result := compositeAdaptiveIcon(icon)
+	if result == nil {
+		t.Fatal("compositeAdaptiveIcon returned nil")
+	}
+
+	_, _, _, a := result.At(size/2, size/2).RGBA()
+	if a == 0 {
+		t.Fatal("expected visible pixel at the center of the composited icon, got fully transparent")
+	}
+}
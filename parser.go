@@ -4,13 +4,13 @@ import (
 	"archive/zip"
 	"bytes"
 	"crypto/md5"
+	"crypto/x509"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"image"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -18,28 +18,83 @@ import (
 
 const (
 	androidExt = ".apk"
+
+	// defaultLocale是Labels中默认(未限定locale)的应用名称对应的key
+	defaultLocale = ""
 )
 
 type AppInfo struct {
-	Name            string      // 应用名称
-	BundleId        string      // 包名
-	Version         string      // 版本名称
-	Build           int         // 版本号
-	Icon            image.Image // app icon
-	Size            int64       // app size in bytes
-	SignatureMd5    string      // app sign
-	SignatureSha1   string      // app sign
-	SignatureSha256 string      // app sign
-	Md5             string      // app md5
-	UsesPermission  []string    //权限
-	SupportOS64     bool        // 是否支持64位
-	SupportOS32     bool        // 是否支持32位
+	Name            string              // 应用名称
+	BundleId        string              // 包名
+	Version         string              // 版本名称
+	Build           int                 // 版本号
+	Icon            image.Image         // app icon
+	Size            int64               // app size in bytes
+	SignatureMd5    string              // app sign
+	SignatureSha1   string              // app sign
+	SignatureSha256 string              // app sign
+	SignatureScheme SignatureScheme     // 签名方案版本(v1/v2/v3)
+	Certificates    []*x509.Certificate // 签名证书，仅含leaf证书一项，链上的其余证书不解析
+	Md5             string              // app md5
+	UsesPermission  []string            //权限
+	SupportOS64     bool                // 是否支持64位
+	SupportOS32     bool                // 是否支持32位
+	Format          Format              // 安装包格式：APK或AAB
+	Modules         []ModuleInfo        // AAB的base/feature/config模块列表，仅Format为FormatAAB时有效
+	Icons           map[int]image.Image // 按dpi分组的图标，key为DensityXXX
+	AdaptiveIcon    *AdaptiveIcon       // 自适应图标的原始前景/背景图层，不存在时为nil
+	Labels          map[string]string   // 按locale分组的应用名称，key为locale(默认locale为"")
+
+	MinSdkVersion        int               // 最低支持的sdk版本
+	TargetSdkVersion     int               // 目标sdk版本
+	CompileSdkVersion    int               // 编译sdk版本
+	Debuggable           bool              // 是否可调试
+	AllowBackup          bool              // 是否允许备份
+	UsesCleartextTraffic bool              // 是否允许明文流量
+	MetaData             map[string]string // <application>下的meta-data
+	Activities           []ComponentInfo   // 声明的activity组件
+	Services             []ComponentInfo   // 声明的service组件
+	Receivers            []ComponentInfo   // 声明的receiver组件
+	Providers            []ComponentInfo   // 声明的provider组件
+	LauncherActivity     string            // 带有LAUNCHER intent-filter的activity
+	UsesFeature          []FeatureInfo     // <uses-feature>声明
+	Splits               []SplitInfo       // 通过NewAppParserFromSplits聚合时的各split明细
+}
+
+// ComponentInfo描述一个四大组件(activity/service/receiver/provider)及其intent-filter
+type ComponentInfo struct {
+	Name          string
+	IntentFilters []IntentFilter
+}
+
+// IntentFilter对应组件下的<intent-filter>
+type IntentFilter struct {
+	Actions    []string
+	Categories []string
+}
+
+// FeatureInfo对应<uses-feature>
+type FeatureInfo struct {
+	Name     string
+	Required bool
 }
 
+const (
+	actionMain       = "android.intent.action.MAIN"
+	categoryLauncher = "android.intent.category.LAUNCHER"
+)
+
 type androidManifest struct {
-	Package        string `xml:"package,attr"`
-	VersionName    string `xml:"versionName,attr"`
-	VersionCode    string `xml:"versionCode,attr"`
+	Package           string `xml:"package,attr"`
+	VersionName       string `xml:"versionName,attr"`
+	VersionCode       string `xml:"versionCode,attr"`
+	CompileSdkVersion string `xml:"compileSdkVersion,attr"`
+	Split             string `xml:"split,attr"`
+	ConfigForSplit    string `xml:"configForSplit,attr"`
+	UsesSdk           struct {
+		MinSdkVersion    string `xml:"minSdkVersion,attr"`
+		TargetSdkVersion string `xml:"targetSdkVersion,attr"`
+	} `xml:"uses-sdk"`
 	UsesPermission []struct {
 		Text string `xml:",chardata"`
 		Name string `xml:"name,attr"`
@@ -49,9 +104,41 @@ type androidManifest struct {
 		Name            string `xml:"name,attr"`
 		ProtectionLevel string `xml:"protectionLevel,attr"`
 	} `xml:"permission"`
+	UsesFeature []struct {
+		Name     string `xml:"name,attr"`
+		Required string `xml:"required,attr"`
+	} `xml:"uses-feature"`
+	// Application为指针类型：config split(仅包含资源/so/dex)没有<application>
+	// 标签，解码后为nil，以此与base/master split区分开。
+	Application *struct {
+		Debuggable           string `xml:"debuggable,attr"`
+		AllowBackup          string `xml:"allowBackup,attr"`
+		UsesCleartextTraffic string `xml:"usesCleartextTraffic,attr"`
+		MetaData             []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"meta-data"`
+		Activity []manifestComponent `xml:"activity"`
+		Service  []manifestComponent `xml:"service"`
+		Receiver []manifestComponent `xml:"receiver"`
+		Provider []manifestComponent `xml:"provider"`
+	} `xml:"application"`
 }
 
-func NewAppParser(name, keyToolPath string, isIcon bool) (*AppInfo, error) {
+// manifestComponent是activity/service/receiver/provider共用的xml结构
+type manifestComponent struct {
+	Name         string `xml:"name,attr"`
+	IntentFilter []struct {
+		Action []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"action"`
+		Category []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"category"`
+	} `xml:"intent-filter"`
+}
+
+func NewAppParser(name string, isIcon bool) (*AppInfo, error) {
 	file, err := os.Open(name)
 	if err != nil {
 		return nil, err
@@ -63,7 +150,9 @@ func NewAppParser(name, keyToolPath string, isIcon bool) (*AppInfo, error) {
 	stat, err := file.Stat()
 	if err != nil {
 		return nil, err
-	} else if filepath.Ext(stat.Name()) != androidExt {
+	}
+	ext := filepath.Ext(stat.Name())
+	if ext != androidExt && ext != aabExt {
 		return nil, errors.New("unknown platform")
 	}
 
@@ -72,17 +161,71 @@ func NewAppParser(name, keyToolPath string, isIcon bool) (*AppInfo, error) {
 		return nil, err
 	}
 
-	var (
-		xmlFile     *zip.File
-		supportOS64 bool
-		supportOS32 bool
-		hasSoFile   bool
-	)
-	for _, f := range reader.File {
-		switch f.Name {
-		case "AndroidManifest.xml":
-			xmlFile = f
+	if ext == aabExt {
+		return parseAppBundle(file, stat.Size(), reader, isIcon)
+	}
+
+	info, errParse := parseApkZip(reader)
+	if errParse != nil {
+		return nil, errParse
+	}
+	apkMd5, _ := getApkMd5(file)
+	info.Md5 = apkMd5
+	info.SignatureMd5, info.SignatureSha1, info.SignatureSha256, info.SignatureScheme, info.Certificates =
+		getSignature(file, stat.Size(), reader)
+
+	icons, labels, adaptiveIcon, errExtra := parseApkIconAndLabel(name)
+	if errExtra != nil {
+		return nil, errExtra
+	}
+	info.Labels = labels
+	info.Name = labels[defaultLocale]
+	if isIcon {
+		info.Icons = icons
+		info.AdaptiveIcon = adaptiveIcon
+		if adaptiveIcon != nil {
+			info.Icon = compositeAdaptiveIcon(adaptiveIcon)
+		} else {
+			info.Icon = pickRepresentativeIcon(icons)
 		}
+	}
+	info.Size = stat.Size()
+
+	return info, err
+}
+
+// parseAppBundle 解析.aab(Android App Bundle)，其manifest为protobuf编码，
+// 解析链路与普通apk不同，因此单独走parseBundleFile。parseProtoManifest目前
+// 只从protobuf manifest中提取了package/versionName/versionCode/
+// uses-permission，尚未覆盖parseApkFile那一条AXML链路解析出的uses-sdk、
+// <application>各项flag、meta-data、四大组件、uses-feature等字段，这些在
+// FormatAAB的AppInfo上会保持零值；isIcon为true时图标/本地化名称的解析同样
+// 依赖AXML resources.arsc链路，AAB尚未支持，因此显式报错而不是静默跳过。
+func parseAppBundle(file *os.File, size int64, reader *zip.Reader, isIcon bool) (*AppInfo, error) {
+	if isIcon {
+		return nil, ErrIconUnsupportedForAAB
+	}
+
+	info, err := parseBundleFile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	apkMd5, _ := getApkMd5(file)
+	info.Md5 = apkMd5
+	info.SignatureMd5, info.SignatureSha1, info.SignatureSha256, info.SignatureScheme, info.Certificates =
+		getSignature(file, size, reader)
+	info.Size = size
+
+	return info, nil
+}
+
+// scanNativeLibSupport遍历zip条目，得出该apk/split中lib/目录下so文件暴露的
+// 64/32位ABI支持情况，hasSoFile标记整个包内是否存在任意so文件。不在这里套用
+// "完全没有so文件就视为支持全部位数"的兜底逻辑，调用方按自己的场景(单体apk
+// 还是split集合中的一员)决定何时套用该兜底。
+func scanNativeLibSupport(reader *zip.Reader) (hasSoFile, supportOS64, supportOS32 bool) {
+	for _, f := range reader.File {
 		if strings.HasSuffix(f.Name, ".so") {
 			hasSoFile = true
 		}
@@ -93,33 +236,37 @@ func NewAppParser(name, keyToolPath string, isIcon bool) (*AppInfo, error) {
 			supportOS32 = true
 		}
 	}
-	info, errParse := parseApkFile(xmlFile)
-	if errParse != nil {
-		return nil, errParse
+	return hasSoFile, supportOS64, supportOS32
+}
+
+// parseApkZip在已经打开的zip.Reader上解析AndroidManifest.xml及lib/目录下的
+// so文件，得出基础AppInfo（不含md5、签名、图标/名称）。NewAppParser与
+// NewAppParserFromReader共用这一步，避免重复遍历zip条目。
+func parseApkZip(reader *zip.Reader) (*AppInfo, error) {
+	var xmlFile *zip.File
+	for _, f := range reader.File {
+		if f.Name == "AndroidManifest.xml" {
+			xmlFile = f
+			break
+		}
 	}
-	// 当前apk支持的系统位数
-	if hasSoFile == false && supportOS64 == false && supportOS32 == false {
+	hasSoFile, supportOS64, supportOS32 := scanNativeLibSupport(reader)
+	info, err := parseApkFile(xmlFile)
+	if err != nil {
+		return nil, err
+	}
+	// 当前apk支持的系统位数：一个完整的单体apk如果完全不带so文件，视为不限制
+	// 位数(支持64位也支持32位)；split集合中单个split的这一兜底并不成立，由
+	// NewAppParserFromSplits在聚合所有split后自行处理，见scanNativeLibSupport。
+	if !hasSoFile && !supportOS64 && !supportOS32 {
 		info.SupportOS64 = true
 		info.SupportOS32 = true
 	} else {
 		info.SupportOS64 = supportOS64
 		info.SupportOS32 = supportOS32
 	}
-	apkMd5, _ := getApkMd5(file)
-	info.Md5 = apkMd5
-	info.SignatureMd5, info.SignatureSha1, info.SignatureSha256 = getSignature(name, keyToolPath)
-
-	icon, label, errExtra := parseApkIconAndLabel(name)
-	if errExtra != nil {
-		return nil, errExtra
-	}
-	info.Name = label
-	if isIcon {
-		info.Icon = icon
-	}
-	info.Size = stat.Size()
-
-	return info, err
+	info.Format = FormatAPK
+	return info, nil
 }
 
 // 解析apk文件
@@ -139,13 +286,79 @@ func parseApkFile(xmlFile *zip.File) (*AppInfo, error) {
 	info.BundleId = manifest.Package
 	info.Version = manifest.VersionName
 	info.Build = versionCode
+	info.CompileSdkVersion, _ = strconv.Atoi(manifest.CompileSdkVersion)
+	info.MinSdkVersion, _ = strconv.Atoi(manifest.UsesSdk.MinSdkVersion)
+	info.TargetSdkVersion, _ = strconv.Atoi(manifest.UsesSdk.TargetSdkVersion)
 
 	for _, permission := range manifest.UsesPermission {
 		info.UsesPermission = append(info.UsesPermission, permission.Name)
 	}
+
+	for _, feature := range manifest.UsesFeature {
+		info.UsesFeature = append(info.UsesFeature, FeatureInfo{
+			Name:     feature.Name,
+			Required: feature.Required != "false",
+		})
+	}
+
+	if app := manifest.Application; app != nil {
+		info.Debuggable = app.Debuggable == "true"
+		info.AllowBackup = app.AllowBackup == "true"
+		info.UsesCleartextTraffic = app.UsesCleartextTraffic == "true"
+
+		for _, meta := range app.MetaData {
+			if info.MetaData == nil {
+				info.MetaData = make(map[string]string)
+			}
+			info.MetaData[meta.Name] = meta.Value
+		}
+
+		info.Activities = parseComponents(app.Activity)
+		info.Services = parseComponents(app.Service)
+		info.Receivers = parseComponents(app.Receiver)
+		info.Providers = parseComponents(app.Provider)
+
+		for _, activity := range info.Activities {
+			for _, filter := range activity.IntentFilters {
+				if containsString(filter.Actions, actionMain) && containsString(filter.Categories, categoryLauncher) {
+					info.LauncherActivity = activity.Name
+				}
+			}
+		}
+	}
+
 	return info, nil
 }
 
+// parseComponents将xml中的组件声明转换为对外暴露的ComponentInfo
+func parseComponents(components []manifestComponent) []ComponentInfo {
+	var result []ComponentInfo
+	for _, c := range components {
+		component := ComponentInfo{Name: c.Name}
+		for _, f := range c.IntentFilter {
+			var filter IntentFilter
+			for _, action := range f.Action {
+				filter.Actions = append(filter.Actions, action.Name)
+			}
+			for _, category := range f.Category {
+				filter.Categories = append(filter.Categories, category.Name)
+			}
+			component.IntentFilters = append(component.IntentFilters, filter)
+		}
+		result = append(result, component)
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // 解析AndroidManifest.xml文件
 func parseAndroidManifest(xmlFile *zip.File) (*androidManifest, error) {
 	rc, err := xmlFile.Open()
@@ -175,25 +388,6 @@ func parseAndroidManifest(xmlFile *zip.File) (*androidManifest, error) {
 	return manifest, nil
 }
 
-// 解析apk图标和名称
-func parseApkIconAndLabel(name string) (image.Image, string, error) {
-	pkg, err := openFile(name)
-	if err != nil {
-		return nil, "", err
-	}
-	defer func() {
-		_ = pkg.close()
-	}()
-
-	icon, _ := pkg.icon(&ResTableConfig{
-		Density: 720,
-	})
-
-	label, _ := pkg.label(nil)
-
-	return icon, label, nil
-}
-
 // 获取apk md5
 func getApkMd5(file *os.File) (string, error) {
 	hash := md5.New()
@@ -203,53 +397,3 @@ func getApkMd5(file *os.File) (string, error) {
 
 	return fmt.Sprintf("%032x", hash.Sum(nil)), nil
 }
-
-// 获取apk签名
-func getSignature(apkPath, keyToolPath string) (string, string, string) {
-	if apkPath == "" || keyToolPath == "" {
-		return "", "", ""
-	}
-	keytoolCmd := exec.Command(keyToolPath, "-printcert", "-jarfile", apkPath)
-
-	// 设置管道连接各个命令
-	var (
-		output       bytes.Buffer
-		resultMD5    string
-		resultSHA1   string
-		resultSHA256 string
-	)
-	keytoolCmd.Stdout = &output
-	// 运行命令
-	if errRun := keytoolCmd.Run(); errRun != nil {
-		return "", "", ""
-	}
-
-	// 将字符串拆分成多行
-	lines := strings.Split(output.String(), "\n")
-	// 匹配规则：包含字符串 "MD5:"
-	for _, line := range lines {
-		if strings.Contains(line, "MD5:") {
-			_, resultMD5, _ = strings.Cut(line, "MD5:")
-			continue
-		}
-		if strings.Contains(line, "SHA1:") {
-			_, resultSHA1, _ = strings.Cut(line, "SHA1:")
-			continue
-		}
-		if strings.Contains(line, "SHA256:") {
-			_, resultSHA256, _ = strings.Cut(line, "SHA256:")
-			continue
-		}
-	}
-	// 将匹配结果拼接成一个新的字符串
-	resultMD5 = strings.Replace(resultMD5, " ", "", -1)
-	resultMD5 = strings.Replace(resultMD5, ":", "", -1)
-
-	resultSHA1 = strings.Replace(resultSHA1, " ", "", -1)
-	resultSHA1 = strings.Replace(resultSHA1, ":", "", -1)
-
-	resultSHA256 = strings.Replace(resultSHA256, " ", "", -1)
-	resultSHA256 = strings.Replace(resultSHA256, ":", "", -1)
-
-	return strings.ToLower(resultMD5), strings.ToLower(resultSHA1), strings.ToLower(resultSHA256)
-}
@@ -0,0 +1,292 @@
+package paser
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SignatureScheme 表示apk的签名方案版本
+type SignatureScheme int
+
+const (
+	SignatureSchemeUnknown SignatureScheme = iota
+	SignatureSchemeV1
+	SignatureSchemeV2
+	SignatureSchemeV3
+)
+
+const (
+	apkSigBlockMagic  = "APK Sig Block 42"
+	apkSigBlockMagicN = 16
+	apkSigBlockV2ID   = 0x7109871a
+	apkSigBlockV3ID   = 0xf05368c0
+)
+
+// getSignature 解析apk签名证书并返回其md5/sha1/sha256指纹、签名方案及证书列表；
+// 证书列表只含第一个signer的leaf证书，链上的其余证书不会返回。
+// 优先解析zip末尾的APK Signing Block(v2/v3)，解析不到时回退解析META-INF下的
+// v1(JAR)签名文件，全程不依赖keytool，因此不再需要JDK环境。
+func getSignature(file io.ReaderAt, size int64, reader *zip.Reader) (md5Hex, sha1Hex, sha256Hex string, scheme SignatureScheme, certs []*x509.Certificate) {
+	cert, schemeV2V3, err := parseApkSigningBlock(file, size)
+	if err == nil && cert != nil {
+		return hashCert(cert, scheme2Scheme(schemeV2V3))
+	}
+
+	cert, err = parseV1Signature(reader)
+	if err != nil || cert == nil {
+		return "", "", "", SignatureSchemeUnknown, nil
+	}
+	return hashCert(cert, SignatureSchemeV1)
+}
+
+func scheme2Scheme(s SignatureScheme) SignatureScheme {
+	return s
+}
+
+func hashCert(cert *x509.Certificate, scheme SignatureScheme) (string, string, string, SignatureScheme, []*x509.Certificate) {
+	md5Sum := md5.Sum(cert.Raw)
+	sha1Sum := sha1.Sum(cert.Raw)
+	sha256Sum := sha256.Sum256(cert.Raw)
+	return fmt.Sprintf("%x", md5Sum), fmt.Sprintf("%x", sha1Sum), fmt.Sprintf("%x", sha256Sum), scheme, []*x509.Certificate{cert}
+}
+
+// parseApkSigningBlock 定位zip中央目录之前的APK Signing Block，优先返回v3签名，
+// 不存在v3时返回v2签名的第一个signer的leaf证书。
+func parseApkSigningBlock(r io.ReaderAt, size int64) (*x509.Certificate, SignatureScheme, error) {
+	cdOffset, err := findCentralDirectoryOffset(r, size)
+	if err != nil {
+		return nil, SignatureSchemeUnknown, err
+	}
+
+	footer := make([]byte, 8+apkSigBlockMagicN)
+	if _, err := r.ReadAt(footer, cdOffset-int64(len(footer))); err != nil {
+		return nil, SignatureSchemeUnknown, err
+	}
+	if string(footer[8:]) != apkSigBlockMagic {
+		return nil, SignatureSchemeUnknown, errors.New("apk signing block not found")
+	}
+	blockSize := int64(binary.LittleEndian.Uint64(footer[:8]))
+	blockStart := cdOffset - blockSize - 8
+
+	sizeHeader := make([]byte, 8)
+	if _, err := r.ReadAt(sizeHeader, blockStart); err != nil {
+		return nil, SignatureSchemeUnknown, err
+	}
+	if int64(binary.LittleEndian.Uint64(sizeHeader)) != blockSize {
+		return nil, SignatureSchemeUnknown, errors.New("apk signing block size mismatch")
+	}
+	// blockSize来自文件内容，必须至少能容纳重复的size字段(8字节)+magic(16字节)，
+	// 否则下面的切片长度计算会下溢为负数。
+	if blockSize < 24 {
+		return nil, SignatureSchemeUnknown, errors.New("apk signing block size too small")
+	}
+
+	pairs := make([]byte, blockSize-8-16)
+	if _, err := r.ReadAt(pairs, blockStart+8); err != nil {
+		return nil, SignatureSchemeUnknown, err
+	}
+
+	var (
+		v2Value []byte
+		v3Value []byte
+	)
+	for off := 0; off+8 <= len(pairs); {
+		rawPairLen := binary.LittleEndian.Uint64(pairs[off : off+8])
+		off += 8
+		// rawPairLen来自文件内容：先做无符号比较再转换为int，避免长度接近
+		// math.MaxUint64时int(rawPairLen)溢出成负数，从而绕过下面本应失败的
+		// 边界检查。
+		if rawPairLen < 4 || rawPairLen > uint64(len(pairs)-off) {
+			break
+		}
+		pairLen := int(rawPairLen)
+		id := binary.LittleEndian.Uint32(pairs[off : off+4])
+		value := pairs[off+4 : off+pairLen]
+		switch id {
+		case apkSigBlockV2ID:
+			v2Value = value
+		case apkSigBlockV3ID:
+			v3Value = value
+		}
+		off += pairLen
+	}
+
+	if v3Value != nil {
+		if cert, err := firstSignerLeafCert(v3Value); err == nil {
+			return cert, SignatureSchemeV3, nil
+		}
+	}
+	if v2Value != nil {
+		if cert, err := firstSignerLeafCert(v2Value); err == nil {
+			return cert, SignatureSchemeV2, nil
+		}
+	}
+	return nil, SignatureSchemeUnknown, errors.New("no v2/v3 signer found")
+}
+
+// findCentralDirectoryOffset 从EOCD中读取zip中央目录的起始偏移量。
+func findCentralDirectoryOffset(r io.ReaderAt, size int64) (int64, error) {
+	const (
+		eocdMinSize  = 22
+		eocdMagic    = 0x06054b50
+		maxCommentSz = 0xffff
+	)
+	searchSize := int64(eocdMinSize + maxCommentSz)
+	if searchSize > size {
+		searchSize = size
+	}
+	buf := make([]byte, searchSize)
+	if _, err := r.ReadAt(buf, size-searchSize); err != nil {
+		return 0, err
+	}
+	for i := len(buf) - eocdMinSize; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(buf[i:i+4]) == eocdMagic {
+			cdOffset := binary.LittleEndian.Uint32(buf[i+16 : i+20])
+			return int64(cdOffset), nil
+		}
+	}
+	return 0, errors.New("EOCD record not found")
+}
+
+// firstSignerLeafCert 解析v2/v3签名块中signer序列，返回第一个signer的leaf证书。
+func firstSignerLeafCert(value []byte) (*x509.Certificate, error) {
+	signers, err := readLenPrefixed(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(signers) < 4 {
+		return nil, errors.New("empty signer sequence")
+	}
+	signer, err := readLenPrefixed(signers)
+	if err != nil {
+		return nil, err
+	}
+
+	signedData, err := readLenPrefixed(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	// signed data: digests, certificates, (additional attributes[, min/max sdk for v3])
+	rest, err := skipLenPrefixedOne(signedData)
+	if err != nil {
+		return nil, err
+	}
+
+	// certificates是长度前缀的证书序列，取其中第一个(leaf)证书
+	certDER, err := readLenPrefixedOne(rest)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(certDER)
+}
+
+// readLenPrefixed 读取一个uint32长度前缀的块，返回其内容。
+func readLenPrefixed(b []byte) ([]byte, error) {
+	if len(b) < 4 {
+		return nil, errors.New("truncated length-prefixed block")
+	}
+	n := int(binary.LittleEndian.Uint32(b[:4]))
+	if n < 0 || 4+n > len(b) {
+		return nil, errors.New("invalid length-prefixed block")
+	}
+	return b[4 : 4+n], nil
+}
+
+// readLenPrefixedOne 读取一个uint32长度前缀的"序列"中的第一个元素。
+func readLenPrefixedOne(b []byte) ([]byte, error) {
+	seq, err := readLenPrefixed(b)
+	if err != nil {
+		return nil, err
+	}
+	return readLenPrefixed(seq)
+}
+
+// skipLenPrefixedOne 跳过一个uint32长度前缀的块，返回其后剩余的数据。
+func skipLenPrefixedOne(b []byte) ([]byte, error) {
+	if len(b) < 4 {
+		return nil, errors.New("truncated length-prefixed block")
+	}
+	n := int(binary.LittleEndian.Uint32(b[:4]))
+	if n < 0 || 4+n > len(b) {
+		return nil, errors.New("invalid length-prefixed block")
+	}
+	return b[4+n:], nil
+}
+
+// parseV1Signature 回退解析META-INF/*.RSA|*.DSA|*.EC中的PKCS#7签名块，提取leaf证书。
+// 用于仅使用v1(JAR)签名的apk。
+func parseV1Signature(reader *zip.Reader) (*x509.Certificate, error) {
+	if reader == nil {
+		return nil, errors.New("no zip reader available")
+	}
+	for _, f := range reader.File {
+		upper := strings.ToUpper(f.Name)
+		if !strings.HasPrefix(upper, "META-INF/") {
+			continue
+		}
+		if !strings.HasSuffix(upper, ".RSA") && !strings.HasSuffix(upper, ".DSA") && !strings.HasSuffix(upper, ".EC") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		der, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			continue
+		}
+		cert, err := parsePKCS7LeafCert(der)
+		if err != nil {
+			continue
+		}
+		return cert, nil
+	}
+	return nil, errors.New("no v1 signature file found")
+}
+
+// pkcs7ContentInfo对应PKCS#7 ContentInfo ::= SEQUENCE { contentType OID, content [0] EXPLICIT ANY }
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// pkcs7SignedData对应SignedData ::= SEQUENCE { version, digestAlgorithms SET,
+// contentInfo, certificates [0] IMPLICIT SET OF Certificate OPTIONAL, ... }
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue `asn1:"set"`
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+func parsePKCS7LeafCert(der []byte) (*x509.Certificate, error) {
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+	if len(signedData.Certificates.Bytes) == 0 {
+		return nil, errors.New("no certificates in PKCS#7 signed data")
+	}
+
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil || len(certs) == 0 {
+		return nil, errors.New("failed to parse X.509 certificate")
+	}
+	return certs[0], nil
+}
@@ -0,0 +1,79 @@
+package paser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// buildEOCDWithSigningBlockFooter构造一个仅包含APK Signing Block尾部(size字段+magic)
+// 紧跟一个最小EOCD记录的zip字节流，blockSize字段可由调用方任意伪造，用于驱动
+// parseApkSigningBlock的边界条件。
+func buildEOCDWithSigningBlockFooter(blockSize uint64) []byte {
+	footer := make([]byte, 24)
+	binary.LittleEndian.PutUint64(footer[:8], blockSize)
+	copy(footer[8:], apkSigBlockMagic)
+
+	cdOffset := uint32(len(footer))
+	eocd := make([]byte, 22)
+	binary.LittleEndian.PutUint32(eocd[0:4], 0x06054b50)
+	binary.LittleEndian.PutUint32(eocd[16:20], cdOffset)
+
+	return append(footer, eocd...)
+}
+
+// 一个伪造size字段为16(小于size字段+magic本身的24字节)的signing block，其起始
+// 处的size字段恰好与footer中的size字段重合，能通过size一致性校验；若不检查下限，
+// pairs := make([]byte, blockSize-8-16)会下溢为负数而panic。该测试确保现在改为
+// 返回错误。
+func TestParseApkSigningBlockRejectsUndersizedBlock(t *testing.T) {
+	buf := buildEOCDWithSigningBlockFooter(16)
+
+	_, _, err := parseApkSigningBlock(bytes.NewReader(buf), int64(len(buf)))
+	if err == nil {
+		t.Fatal("expected error for undersized APK signing block, got nil")
+	}
+}
+
+func TestParseApkSigningBlockRejectsTruncatedBlock(t *testing.T) {
+	buf := buildEOCDWithSigningBlockFooter(23)
+
+	_, _, err := parseApkSigningBlock(bytes.NewReader(buf), int64(len(buf)))
+	if err == nil {
+		t.Fatal("expected error for truncated APK signing block, got nil")
+	}
+}
+
+// 一个伪造的ID-value pair长度字段(接近math.MaxInt64)曾经导致int(rawPairLen)
+// 溢出成负数，绕过"rawPairLen > len(pairs)-off"的边界检查，使得后续切片
+// 操作panic。该测试构造一个size字段、pairs区与footer都自洽的signing block，
+// 确保现在改为返回错误而不是panic。
+func TestParseApkSigningBlockRejectsOversizedPairLength(t *testing.T) {
+	const blockSize = 40 // pairs区长度为blockSize-8-16=16，footer紧跟在pairs区之后
+
+	buf := make([]byte, 0, 8+blockSize+22)
+	sizeHeader := make([]byte, 8)
+	binary.LittleEndian.PutUint64(sizeHeader, blockSize)
+	buf = append(buf, sizeHeader...) // blockStart处重复的size字段
+
+	pairs := make([]byte, blockSize-8-16)
+	binary.LittleEndian.PutUint64(pairs[:8], 0x7fffffffffffffff)
+	binary.LittleEndian.PutUint32(pairs[8:12], apkSigBlockV2ID)
+	buf = append(buf, pairs...)
+
+	footer := make([]byte, 24)
+	binary.LittleEndian.PutUint64(footer[:8], blockSize)
+	copy(footer[8:], apkSigBlockMagic)
+	buf = append(buf, footer...)
+
+	cdOffset := uint32(len(buf))
+	eocd := make([]byte, 22)
+	binary.LittleEndian.PutUint32(eocd[0:4], 0x06054b50)
+	binary.LittleEndian.PutUint32(eocd[16:20], cdOffset)
+	buf = append(buf, eocd...)
+
+	_, _, err := parseApkSigningBlock(bytes.NewReader(buf), int64(len(buf)))
+	if err == nil {
+		t.Fatal("expected error for oversized APK signing block pair length, got nil")
+	}
+}
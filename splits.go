@@ -0,0 +1,213 @@
+package paser
+
+import (
+	"archive/zip"
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SplitInfo描述一个Split APK（base/master split或config split）的元信息。
+type SplitInfo struct {
+	Name      string // 文件名（不含路径）
+	ConfigFor string // configForSplit属性，指向目标feature split，master split为空
+	ABI       string // lib split对应的ABI，如 arm64-v8a，非lib split为空
+	Density   int    // 资源split对应的dpi桶(DensityXXX)，非density split为0
+	Locale    string // 资源split对应的locale，非locale split为空
+	Size      int64  // 文件大小
+}
+
+// densityQualifiers将config split的资源限定符映射到DensityXXX常量。
+var densityQualifiers = map[string]int{
+	"ldpi": DensityLDPI, "mdpi": DensityMDPI, "tvdpi": DensityTVDPI,
+	"hdpi": DensityHDPI, "xhdpi": DensityXHDPI, "xxhdpi": DensityXXHDPI,
+	"xxxhdpi": DensityXXXHDPI, "anydpi": DensityAnyDPI,
+}
+
+// abiQualifiers将bundletool生成的split限定符(会把ABI名中的"-"替换为"_"，
+// 如 "arm64_v8a"、"armeabi_v7a")映射回标准ABI名称。x86_64的ABI名本身就带
+// 下划线，因此不能用统一的"_"→"-"替换规则处理，这里按限定符逐个列出。
+var abiQualifiers = map[string]string{
+	"armeabi":     "armeabi",
+	"armeabi_v7a": "armeabi-v7a",
+	"arm64_v8a":   "arm64-v8a",
+	"x86":         "x86",
+	"x86_64":      "x86_64",
+}
+
+// NewAppParserFromSplits解析一组Split APK（一个base/master apk加若干config
+// split），并将权限、ABI支持、图标、总大小聚合到同一个AppInfo中，便于校验
+// 一次Play商店式分发所需的完整split集合是否完整。
+func NewAppParserFromSplits(paths []string) (*AppInfo, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no split apk provided")
+	}
+
+	type splitParse struct {
+		info        *AppInfo
+		manifest    *androidManifest
+		size        int64
+		path        string
+		hasSoFile   bool
+		supportOS64 bool
+		supportOS32 bool
+	}
+
+	parsedSplits := make([]splitParse, 0, len(paths))
+	for _, path := range paths {
+		info, manifest, size, hasSoFile, supportOS64, supportOS32, err := parseSplitAPK(path)
+		if err != nil {
+			return nil, err
+		}
+		parsedSplits = append(parsedSplits, splitParse{info, manifest, size, path, hasSoFile, supportOS64, supportOS32})
+	}
+
+	var base *AppInfo
+	for _, p := range parsedSplits {
+		if p.manifest.Application != nil {
+			base = p.info
+			break
+		}
+	}
+	if base == nil {
+		return nil, errors.New("base apk (containing <application>) not found among splits")
+	}
+
+	var (
+		totalSize int64
+		splits    []SplitInfo
+		permSet   = map[string]struct{}{}
+		icons     = map[int]image.Image{}
+		libSignal []nativeLibSignal
+	)
+	for _, p := range parsedSplits {
+		totalSize += p.size
+		for _, perm := range p.info.UsesPermission {
+			permSet[perm] = struct{}{}
+		}
+		libSignal = append(libSignal, nativeLibSignal{hasSoFile: p.hasSoFile, supportOS64: p.supportOS64, supportOS32: p.supportOS32})
+
+		abi, density, locale := classifySplit(p.manifest.Split)
+		splits = append(splits, SplitInfo{
+			Name:      filepath.Base(p.path),
+			ConfigFor: p.manifest.ConfigForSplit,
+			ABI:       abi,
+			Density:   density,
+			Locale:    locale,
+			Size:      p.size,
+		})
+
+		if splitIcons, _, _, err := parseApkIconAndLabel(p.path); err == nil {
+			for d, icon := range splitIcons {
+				icons[d] = icon
+			}
+		}
+	}
+
+	base.SupportOS64, base.SupportOS32 = aggregateNativeLibSupport(libSignal)
+
+	base.UsesPermission = base.UsesPermission[:0]
+	for perm := range permSet {
+		base.UsesPermission = append(base.UsesPermission, perm)
+	}
+	base.Icons = icons
+	base.Icon = pickRepresentativeIcon(icons)
+	base.Size = totalSize
+	base.Splits = splits
+
+	return base, nil
+}
+
+// classifySplit将config split的split属性(如 "config.arm64_v8a"、"config.xxhdpi")
+// 解析为ABI/密度/locale限定符；非config split(split属性为空或为feature split名)
+// 返回全部零值。
+func classifySplit(split string) (abi string, density int, locale string) {
+	qualifier := strings.TrimPrefix(split, "config.")
+	if qualifier == split || qualifier == "" {
+		return "", 0, ""
+	}
+	if canonical, ok := abiQualifiers[qualifier]; ok {
+		return canonical, 0, ""
+	}
+	if d, ok := densityQualifiers[qualifier]; ok {
+		return "", d, ""
+	}
+	return "", 0, qualifier
+}
+
+// nativeLibSignal记录单个split里scanNativeLibSupport的原始扫描结果，供
+// aggregateNativeLibSupport跨split聚合，不携带parseApkZip那套单体apk口径的
+// "无so即支持全部位数"兜底。
+type nativeLibSignal struct {
+	hasSoFile   bool
+	supportOS64 bool
+	supportOS32 bool
+}
+
+// aggregateNativeLibSupport跨一组split聚合ABI支持情况：只有当整个split集合
+// 中完全没有任何so文件时，才视为不限制位数(都支持)；只要任意split暴露了
+// 某个ABI的lib目录，位数支持就只由实际扫描到的ABI决定，不再套用单体apk的
+// 兜底逻辑——否则一个纯资源config split(没有自己的so文件)会把真正只发布了
+// 单一ABI lib split的结果错误地拉成"两种位数都支持"。
+func aggregateNativeLibSupport(signals []nativeLibSignal) (supportOS64, supportOS32 bool) {
+	var hasSoFile bool
+	for _, s := range signals {
+		hasSoFile = hasSoFile || s.hasSoFile
+		supportOS64 = supportOS64 || s.supportOS64
+		supportOS32 = supportOS32 || s.supportOS32
+	}
+	if !hasSoFile && !supportOS64 && !supportOS32 {
+		return true, true
+	}
+	return supportOS64, supportOS32
+}
+
+// parseSplitAPK解析单个split apk文件，返回其AppInfo(权限/ABI等)、原始
+// androidManifest(用于读取split/configForSplit属性及判断<application>是否
+// 存在)、文件大小，以及该split自身(不套用"无so即支持全部位数"兜底的)原始
+// so扫描结果，供NewAppParserFromSplits跨split聚合ABI支持情况。
+func parseSplitAPK(path string) (info *AppInfo, manifest *androidManifest, size int64, hasSoFile, supportOS64, supportOS32 bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, 0, false, false, false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, nil, 0, false, false, false, err
+	}
+
+	reader, err := zip.NewReader(file, stat.Size())
+	if err != nil {
+		return nil, nil, 0, false, false, false, err
+	}
+
+	var xmlFile *zip.File
+	for _, f := range reader.File {
+		if f.Name == "AndroidManifest.xml" {
+			xmlFile = f
+			break
+		}
+	}
+	if xmlFile == nil {
+		return nil, nil, 0, false, false, false, errors.New("AndroidManifest.xml not found in " + path)
+	}
+
+	manifest, err = parseAndroidManifest(xmlFile)
+	if err != nil {
+		return nil, nil, 0, false, false, false, err
+	}
+
+	info, err = parseApkZip(reader)
+	if err != nil {
+		return nil, nil, 0, false, false, false, err
+	}
+	hasSoFile, supportOS64, supportOS32 = scanNativeLibSupport(reader)
+
+	return info, manifest, stat.Size(), hasSoFile, supportOS64, supportOS32, nil
+}
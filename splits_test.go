@@ -0,0 +1,72 @@
+package paser
+
+import "testing"
+
+// bundletool生成的ABI config split用下划线命名(如 "config.arm64_v8a"，见请求
+// 中给出的split_config.arm64_v8a.apk示例)，曾经因为只对返回值而非查表key做
+// "_"→"-"归一化而查表落空，导致ABI识别失败、被误判为locale限定符。
+func TestClassifySplitNormalizesUnderscoreABIQualifiers(t *testing.T) {
+	cases := []struct {
+		split   string
+		wantABI string
+	}{
+		{"config.arm64_v8a", "arm64-v8a"},
+		{"config.armeabi_v7a", "armeabi-v7a"},
+		{"config.x86_64", "x86_64"},
+		{"config.x86", "x86"},
+	}
+
+	for _, c := range cases {
+		abi, density, locale := classifySplit(c.split)
+		if abi != c.wantABI {
+			t.Errorf("classifySplit(%q) abi = %q, want %q", c.split, abi, c.wantABI)
+		}
+		if density != 0 || locale != "" {
+			t.Errorf("classifySplit(%q) density/locale = %d/%q, want 0/\"\"", c.split, density, locale)
+		}
+	}
+}
+
+// NewAppParserFromSplits曾经直接OR各split.info.SupportOS64/32(单体apk口径，
+// 对"split完全不含so文件"会兜底为都支持)，导致一个纯资源config split(没有
+// 自己的so文件)把只发布了单一ABI lib split的结果错误地拉成"两种位数都支持"。
+func TestAggregateNativeLibSupportIgnoresPerSplitFallback(t *testing.T) {
+	// base/master split + 一个仅发布arm64-v8a的lib split + 一个纯资源config
+	// split(无so文件)：整体应当只支持64位，不应被资源split的单体兜底拉成
+	// 两种位数都支持。
+	signals := []nativeLibSignal{
+		{hasSoFile: false, supportOS64: false, supportOS32: false}, // base/master split，无so
+		{hasSoFile: true, supportOS64: true, supportOS32: false},   // split_config.arm64_v8a.apk
+		{hasSoFile: false, supportOS64: false, supportOS32: false}, // split_config.xxhdpi.apk，无so
+	}
+
+	supportOS64, supportOS32 := aggregateNativeLibSupport(signals)
+	if !supportOS64 || supportOS32 {
+		t.Fatalf("aggregateNativeLibSupport(%v) = (%v, %v), want (true, false)", signals, supportOS64, supportOS32)
+	}
+}
+
+// 当split集合里没有任何split携带so文件时，才应当回退到"不限制位数"。
+func TestAggregateNativeLibSupportFallsBackWhenNoSplitHasSoFile(t *testing.T) {
+	signals := []nativeLibSignal{
+		{hasSoFile: false, supportOS64: false, supportOS32: false},
+		{hasSoFile: false, supportOS64: false, supportOS32: false},
+	}
+
+	supportOS64, supportOS32 := aggregateNativeLibSupport(signals)
+	if !supportOS64 || !supportOS32 {
+		t.Fatalf("aggregateNativeLibSupport(%v) = (%v, %v), want (true, true)", signals, supportOS64, supportOS32)
+	}
+}
+
+func TestClassifySplitDensityAndLocale(t *testing.T) {
+	abi, density, locale := classifySplit("config.xxhdpi")
+	if abi != "" || density != DensityXXHDPI || locale != "" {
+		t.Errorf("classifySplit(config.xxhdpi) = %q/%d/%q, want \"\"/%d/\"\"", abi, density, locale, DensityXXHDPI)
+	}
+
+	abi, density, locale = classifySplit("config.en")
+	if abi != "" || density != 0 || locale != "en" {
+		t.Errorf("classifySplit(config.en) = %q/%d/%q, want \"\"/0/\"en\"", abi, density, locale)
+	}
+}
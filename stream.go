@@ -0,0 +1,75 @@
+package paser
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrIconPathRequired在调用方设置了opts.IsIcon却未提供opts.Path时返回：
+// 图标/名称解析目前仍依赖按路径重新打开文件的资源表解析链路，对真正的远程/
+// 内存数据源(S3、HTTP Range、上传内容等)无能为力，宁可显式报错也不要静默
+// 返回一个看似正常、实则没有图标/名称的AppInfo。
+var ErrIconPathRequired = errors.New("paser: ParserOptions.IsIcon requires ParserOptions.Path (icon/label parsing is not yet reader-based)")
+
+// ParserOptions控制NewAppParserFromReader的解析行为。
+type ParserOptions struct {
+	// IsIcon控制是否解析图标/本地化名称。
+	IsIcon bool
+	// Path为本地文件路径。图标/名称的解析依赖按路径重新打开文件的资源表
+	// 解析链路，因此IsIcon为true时必须提供Path；对真正的远程/内存数据源，
+	// 请将IsIcon设为false，NewAppParserFromReader会跳过图标/名称解析而不
+	// 是报错。
+	Path string
+}
+
+// NewAppParserFromReader在单个io.ReaderAt上完成manifest、权限、签名与md5的
+// 解析，共用同一个zip.Reader，无需像NewAppParser那样依赖文件路径重复
+// os.Open。这使得直接解析S3/GCS的RangeReader、HTTP Range请求或内存中的
+// 上传内容成为可能。
+func NewAppParserFromReader(r io.ReaderAt, size int64, opts ParserOptions) (*AppInfo, error) {
+	if opts.IsIcon && opts.Path == "" {
+		return nil, ErrIconPathRequired
+	}
+
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := parseApkZip(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(r, 0, size)); err != nil {
+		return nil, err
+	}
+	info.Md5 = fmt.Sprintf("%032x", hash.Sum(nil))
+
+	info.SignatureMd5, info.SignatureSha1, info.SignatureSha256, info.SignatureScheme, info.Certificates =
+		getSignature(r, size, reader)
+
+	info.Size = size
+
+	if opts.IsIcon {
+		icons, labels, adaptiveIcon, errExtra := parseApkIconAndLabel(opts.Path)
+		if errExtra != nil {
+			return nil, errExtra
+		}
+		info.Labels = labels
+		info.Name = labels[defaultLocale]
+		info.Icons = icons
+		info.AdaptiveIcon = adaptiveIcon
+		if adaptiveIcon != nil {
+			info.Icon = compositeAdaptiveIcon(adaptiveIcon)
+		} else {
+			info.Icon = pickRepresentativeIcon(icons)
+		}
+	}
+
+	return info, nil
+}
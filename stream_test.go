@@ -0,0 +1,14 @@
+package paser
+
+import "testing"
+
+// opts.IsIcon为true但opts.Path为空时，曾经被静默忽略(图标/名称解析直接跳过，
+// 返回的AppInfo看起来正常却没有图标/名称)。该校验在解析zip/签名等工作开始
+// 之前就完成，因此这里传入nil reader也不会影响断言：该测试确保现在显式
+// 返回ErrIconPathRequired。
+func TestNewAppParserFromReaderRequiresPathForIcon(t *testing.T) {
+	_, err := NewAppParserFromReader(nil, 0, ParserOptions{IsIcon: true, Path: ""})
+	if err != ErrIconPathRequired {
+		t.Fatalf("NewAppParserFromReader with IsIcon=true and empty Path: err = %v, want ErrIconPathRequired", err)
+	}
+}